@@ -7,12 +7,38 @@ import (
 	"io"
 )
 
+// Version selects a SOAP envelope shape: the envelope namespace and
+// encodingStyle attribute, the Fault element shape, and the Content-Type
+// used to send the message. Write and Read default to SOAP11.
+type Version int
+
+const (
+	// SOAP11 is SOAP 1.1 (http://schemas.xmlsoap.org/soap/envelope/), the
+	// version understood by essentially all UPnP devices.
+	SOAP11 Version = iota
+	// SOAP12 is SOAP 1.2 (http://www.w3.org/2003/05/soap-envelope), used
+	// by some non-UPnP SOAP stacks this package is reused against.
+	SOAP12
+)
+
+// soap11NS and soap12NS are the two envelope namespaces; soap12NS's
+// presence on the root Envelope element is how Read tells them apart, and
+// both are used to correctly namespace the mustUnderstand header
+// attribute when writing.
+const (
+	soap11NS = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12NS = "http://www.w3.org/2003/05/soap-envelope"
+)
+
 // FaultDetail carries XML-encoded application-specific Fault details.
 type FaultDetail struct {
 	Raw []byte `xml:",innerxml"`
 }
 
-// Fault implements error, and contains SOAP fault information.
+// Fault implements error, and contains SOAP fault information. Read
+// populates it from either a SOAP 1.1 Fault (faultcode/faultstring) or a
+// SOAP 1.2 one (Code/Value, Reason/Text), so callers only ever need to
+// look at these fields.
 type Fault struct {
 	Code   string      `xml:"faultcode"`
 	String string      `xml:"faultstring"`
@@ -26,13 +52,18 @@ func (fe *Fault) Error() string {
 
 // Various "constant" bytes used in the written envelope.
 var (
-	envOpen  = []byte(xml.Header + `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
-	env1     = []byte(`<u:`)
-	env2     = []byte(` xmlns:u="`)
-	env3     = []byte(`">`)
-	env4     = []byte(`</u:`)
-	env5     = []byte(`>`)
-	envClose = []byte(`</s:Body></s:Envelope>`)
+	soap11EnvOpen = []byte(xml.Header + `<s:Envelope xmlns:s="` + soap11NS + `" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">`)
+	soap12EnvOpen = []byte(xml.Header + `<s:Envelope xmlns:s="` + soap12NS + `">`)
+	envOpen       = soap11EnvOpen // kept for the streaming ActionEncoder, which is always SOAP 1.1.
+	bodyOpen      = []byte(`<s:Body>`)
+	headerOpen    = []byte(`<s:Header>`)
+	headerClose   = []byte(`</s:Header>`)
+	env1          = []byte(`<u:`)
+	env2          = []byte(` xmlns:u="`)
+	env3          = []byte(`">`)
+	env4          = []byte(`</u:`)
+	env5          = []byte(`>`)
+	envClose      = []byte(`</s:Body></s:Envelope>`)
 )
 
 // Action wraps a SOAP action to be read or written as part of a SOAP envelope.
@@ -40,6 +71,12 @@ type Action struct {
 	// XMLName specifies the XML element namespace (URI) and name. Together
 	// these identify the SOAP action.
 	XMLName xml.Name
+	// Headers, if non-empty, are encoded as children of <s:Header> when
+	// writing. When reading, each incoming header element is matched
+	// against these entries by HeaderName and decoded in place; an
+	// unmatched header marked s:mustUnderstand="1" causes Read to fail
+	// with a *MustUnderstandError.
+	Headers []HeaderEntry `xml:"-"`
 	// Args is an arbitrary struct containing fields for encoding or decoding
 	// arguments. See https://pkg.go.dev/encoding/xml@go1.17.1#Marshal and
 	// https://pkg.go.dev/encoding/xml@go1.17.1#Unmarshal for details on
@@ -47,9 +84,24 @@ type Action struct {
 	Args any `xml:",any"`
 }
 
-// Write marshals a SOAP envelope to the writer. Errors can be from the writer
-// or XML encoding.
-func Write(w io.Writer, action *Action) error {
+// Writer writes SOAP envelopes for a configured Version. The zero Writer
+// defaults to SOAP11, matching the package-level Write.
+type Writer struct {
+	Version Version
+}
+
+// ContentType reports the Content-Type a caller should send alongside the
+// bytes produced by Write, for this Writer's Version.
+func (sw Writer) ContentType() string {
+	if sw.Version == SOAP12 {
+		return `application/soap+xml; charset="utf-8"`
+	}
+	return `text/xml; charset="utf-8"`
+}
+
+// Write marshals a SOAP envelope to w. Errors can be from the writer or
+// XML encoding.
+func (sw Writer) Write(w io.Writer, action *Action) error {
 	// Experiments with one router have shown that it 500s for requests where
 	// the outer default xmlns is set to the SOAP namespace, and then
 	// reassigning the default namespace within that to the service namespace.
@@ -58,7 +110,21 @@ func Write(w io.Writer, action *Action) error {
 	// Resolving https://github.com/golang/go/issues/9519 might remove the need
 	// for this workaround.
 
-	_, err := w.Write(envOpen)
+	open := soap11EnvOpen
+	if sw.Version == SOAP12 {
+		open = soap12EnvOpen
+	}
+
+	_, err := w.Write(open)
+	if err != nil {
+		return err
+	}
+	if len(action.Headers) > 0 {
+		if err := writeHeaders(w, action.Headers, sw.Version); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(bodyOpen)
 	if err != nil {
 		return err
 	}
@@ -107,35 +173,129 @@ func Write(w io.Writer, action *Action) error {
 	return err
 }
 
-// Read unmarshals a SOAP envelope from the reader. Errors can either be from
-// the reader, XML decoding, or a *Fault.
-func Read(r io.Reader, action *Action) error {
-	env := envelope{
-		Body: body{
-			Action: action,
-		},
-	}
+// Write marshals a SOAP 1.1 envelope to the writer. Errors can be from the
+// writer or XML encoding. Use Writer{Version: SOAP12}.Write for SOAP 1.2.
+func Write(w io.Writer, action *Action) error {
+	return Writer{}.Write(w, action)
+}
+
+// Reader reads SOAP envelopes. The zero Reader accepts either SOAP11 or
+// SOAP12 envelopes, detected from the root Envelope element's namespace.
+type Reader struct{}
 
+// Read unmarshals a SOAP envelope from r into action. Errors can either
+// be from the reader, XML decoding, or a *Fault.
+func (Reader) Read(r io.Reader, action *Action) error {
 	dec := xml.NewDecoder(r)
-	err := dec.Decode(&env)
+
+	envStart, err := nextStartElement(dec) // <s:Envelope>
 	if err != nil {
 		return err
 	}
+	if envStart.Name.Local != "Envelope" {
+		return fmt.Errorf("envelope: root element %s is not a SOAP Envelope", envStart.Name)
+	}
+	version := SOAP11
+	envNS := soap11NS
+	if envStart.Name.Space == soap12NS {
+		version = SOAP12
+		envNS = soap12NS
+	}
 
-	if env.Body.Fault != nil {
-		return env.Body.Fault
+	for {
+		start, err := nextStartElement(dec)
+		if err != nil {
+			return err
+		}
+		if start.Name.Space == envNS {
+			switch start.Name.Local {
+			case "Header":
+				if err := readHeaders(dec, action.Headers); err != nil {
+					return err
+				}
+				continue
+			case "Body":
+				return readBody(dec, action, version)
+			}
+		}
+		if err := dec.Skip(); err != nil {
+			return err
+		}
 	}
+}
 
-	return nil
+// Read unmarshals a SOAP envelope from the reader, accepting either SOAP
+// 1.1 or SOAP 1.2. Errors can either be from the reader, XML decoding, or
+// a *Fault.
+func Read(r io.Reader, action *Action) error {
+	return Reader{}.Read(r, action)
 }
 
-type envelope struct {
-	XMLName       xml.Name `xml:"http://schemas.xmlsoap.org/soap/envelope/ Envelope"`
-	EncodingStyle string   `xml:"http://schemas.xmlsoap.org/soap/envelope/ encodingStyle,attr"`
-	Body          body     `xml:"http://schemas.xmlsoap.org/soap/envelope/ Body"`
+// nextStartElement advances dec past any intervening tokens (character
+// data, processing instructions, comments) and returns the next start
+// element.
+func nextStartElement(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
 }
 
-type body struct {
-	Fault  *Fault  `xml:"Fault"`
-	Action *Action `xml:",any"`
-}
\ No newline at end of file
+func readBody(dec *xml.Decoder, action *Action, version Version) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "Fault" {
+				return decodeFault(dec, t, version)
+			}
+			return dec.DecodeElement(action, &t)
+		case xml.EndElement:
+			return nil // empty body
+		}
+	}
+}
+
+// decodeFault decodes a Fault element, normalizing the SOAP 1.2
+// Code/Value + Reason/Text shape into the same Fault fields SOAP 1.1
+// populates directly.
+func decodeFault(dec *xml.Decoder, start xml.StartElement, version Version) error {
+	if version != SOAP12 {
+		var f Fault
+		if err := dec.DecodeElement(&f, &start); err != nil {
+			return err
+		}
+		return &f
+	}
+
+	var raw soap12Fault
+	if err := dec.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+	return &Fault{
+		Code:   raw.Code.Value,
+		String: raw.Reason.Text,
+		Detail: raw.Detail,
+	}
+}
+
+// soap12Fault mirrors the SOAP 1.2 Fault shape
+// (env:Code/env:Value, env:Reason/env:Text, env:Detail), which Read
+// normalizes into the SOAP 1.1-shaped Fault.
+type soap12Fault struct {
+	Code struct {
+		Value string `xml:"Value"`
+	} `xml:"Code"`
+	Reason struct {
+		Text string `xml:"Text"`
+	} `xml:"Reason"`
+	Detail FaultDetail `xml:"Detail"`
+}
@@ -0,0 +1,72 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type echoArgs struct {
+	Value string `xml:"Value"`
+}
+
+func TestWriterReadSOAP12RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	action := &Action{
+		XMLName: xml.Name{Space: "urn:test", Local: "Echo"},
+		Args:    echoArgs{Value: "hi"},
+	}
+	w := Writer{Version: SOAP12}
+	if err := w.Write(&buf, action); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if ct := w.ContentType(); !strings.Contains(ct, "application/soap+xml") {
+		t.Errorf("ContentType = %q, want application/soap+xml", ct)
+	}
+
+	got := &Action{Args: &echoArgs{}}
+	if err := Read(&buf, got); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Args.(*echoArgs).Value != "hi" {
+		t.Errorf("Value = %q, want %q", got.Args.(*echoArgs).Value, "hi")
+	}
+}
+
+func TestReadSOAP12Fault(t *testing.T) {
+	raw := `<?xml version="1.0"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+<env:Body><env:Fault>
+<env:Code><env:Value>env:Sender</env:Value></env:Code>
+<env:Reason><env:Text>bad request</env:Text></env:Reason>
+</env:Fault></env:Body></env:Envelope>`
+
+	action := &Action{Args: &noArgs{}}
+	err := Read(strings.NewReader(raw), action)
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Read err = %v, want *Fault", err)
+	}
+	if fault.String != "bad request" {
+		t.Errorf("fault.String = %q, want %q", fault.String, "bad request")
+	}
+}
+
+func TestReadRejectsNonEnvelopeRoot(t *testing.T) {
+	raw := `<?xml version="1.0"?><NotAnEnvelope/>`
+	action := &Action{Args: &noArgs{}}
+	if err := Read(strings.NewReader(raw), action); err == nil {
+		t.Fatal("Read err = nil, want error for non-Envelope root")
+	}
+}
+
+func TestReadRejectsUnrelatedDocument(t *testing.T) {
+	raw := `<?xml version="1.0"?><html><body><Header/></body></html>`
+	action := &Action{Args: &noArgs{}}
+	if err := Read(strings.NewReader(raw), action); err == nil {
+		t.Fatal("Read err = nil, want error for non-SOAP document")
+	}
+}
@@ -0,0 +1,109 @@
+package envelope
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// HeaderEntry is implemented by values that can appear as children of the
+// SOAP <s:Header> element: encoded when set on an outgoing Action, and
+// matched by HeaderName against incoming header elements when reading.
+type HeaderEntry interface {
+	// HeaderName reports the namespace and local name of the header
+	// element this value encodes as, or decodes from.
+	HeaderName() xml.Name
+}
+
+// MustUnderstandError is returned by Read when an incoming header element
+// is marked s:mustUnderstand="1" and no entry in the Action's Headers
+// matches its name.
+type MustUnderstandError struct {
+	Name xml.Name
+}
+
+func (e *MustUnderstandError) Error() string {
+	return fmt.Sprintf("envelope: header %s is marked mustUnderstand but no matching HeaderEntry was registered", e.Name)
+}
+
+// MustUnderstandHeader may be implemented by a HeaderEntry to have Write
+// mark it s:mustUnderstand="1" (in the envelope namespace matching the
+// Version being written), so a receiver that does not recognize the
+// header rejects the message instead of silently ignoring it.
+type MustUnderstandHeader interface {
+	HeaderEntry
+	MustUnderstand() bool
+}
+
+func writeHeaders(w io.Writer, headers []HeaderEntry, version Version) error {
+	if _, err := w.Write(headerOpen); err != nil {
+		return err
+	}
+	ns := soap11NS
+	if version == SOAP12 {
+		ns = soap12NS
+	}
+	enc := xml.NewEncoder(w)
+	for _, h := range headers {
+		start := xml.StartElement{Name: h.HeaderName()}
+		if mu, ok := h.(MustUnderstandHeader); ok && mu.MustUnderstand() {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Space: ns, Local: "mustUnderstand"}, Value: "1"})
+		}
+		if err := enc.EncodeElement(h, start); err != nil {
+			return err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(headerClose)
+	return err
+}
+
+// readHeaders consumes the children of an already-opened <s:Header>
+// element, decoding any that match a HeaderEntry in headers by name and
+// skipping the rest. It returns *MustUnderstandError for an unmatched
+// header flagged s:mustUnderstand="1".
+func readHeaders(dec *xml.Decoder, headers []HeaderEntry) error {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if entry, ok := findHeaderEntry(headers, t.Name); ok {
+				if err := dec.DecodeElement(entry, &t); err != nil {
+					return err
+				}
+				continue
+			}
+			if headerMustUnderstand(t) {
+				return &MustUnderstandError{Name: t.Name}
+			}
+			if err := dec.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func findHeaderEntry(headers []HeaderEntry, name xml.Name) (HeaderEntry, bool) {
+	for _, h := range headers {
+		if h.HeaderName() == name {
+			return h, true
+		}
+	}
+	return nil, false
+}
+
+func headerMustUnderstand(start xml.StartElement) bool {
+	for _, a := range start.Attr {
+		if a.Name.Local == "mustUnderstand" {
+			return a.Value == "1" || a.Value == "true"
+		}
+	}
+	return false
+}
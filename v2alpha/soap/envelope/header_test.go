@@ -0,0 +1,124 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testHeader struct {
+	XMLName xml.Name `xml:"http://example.com/test Token"`
+	Value   string   `xml:"http://example.com/test Value"`
+}
+
+func (h *testHeader) HeaderName() xml.Name {
+	return xml.Name{Space: "http://example.com/test", Local: "Token"}
+}
+
+type noArgs struct{}
+
+func TestHeaderWriteReadRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writeAction := &Action{
+		XMLName: xml.Name{Space: "urn:test", Local: "DoThing"},
+		Headers: []HeaderEntry{&testHeader{Value: "hello"}},
+		Args:    noArgs{},
+	}
+	if err := Write(&buf, writeAction); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got := &testHeader{}
+	readAction := &Action{Headers: []HeaderEntry{got}, Args: &noArgs{}}
+	if err := Read(&buf, readAction); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.Value != "hello" {
+		t.Errorf("Value = %q, want %q", got.Value, "hello")
+	}
+}
+
+func TestReadMustUnderstandError(t *testing.T) {
+	raw := xml.Header + `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">` +
+		`<s:Header><x:Unknown xmlns:x="urn:x" s:mustUnderstand="1"/></s:Header>` +
+		`<s:Body><x:Foo xmlns:x="urn:x"></x:Foo></s:Body></s:Envelope>`
+
+	action := &Action{Args: &noArgs{}}
+	err := Read(strings.NewReader(raw), action)
+
+	var muErr *MustUnderstandError
+	if !errors.As(err, &muErr) {
+		t.Fatalf("Read err = %v, want *MustUnderstandError", err)
+	}
+	if muErr.Name.Local != "Unknown" {
+		t.Errorf("Name.Local = %q, want %q", muErr.Name.Local, "Unknown")
+	}
+}
+
+func findAttr(data []byte, elemLocal, attrLocal string) (xml.Attr, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.Attr{}, false
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok || se.Name.Local != elemLocal {
+			continue
+		}
+		for _, a := range se.Attr {
+			if a.Name.Local == attrLocal {
+				return a, true
+			}
+		}
+	}
+}
+
+func TestWriteSecurityHeaderNamespacesMustUnderstand(t *testing.T) {
+	token, err := NewUsernameToken("bob", "hunter2")
+	if err != nil {
+		t.Fatalf("NewUsernameToken: %v", err)
+	}
+	action := &Action{
+		XMLName: xml.Name{Space: "urn:test", Local: "DoThing"},
+		Headers: []HeaderEntry{NewSecurity(token)},
+		Args:    noArgs{},
+	}
+
+	for _, tc := range []struct {
+		name    string
+		version Version
+		wantNS  string
+	}{
+		{"SOAP11", SOAP11, soap11NS},
+		{"SOAP12", SOAP12, soap12NS},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := (Writer{Version: tc.version}).Write(&buf, action); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			attr, ok := findAttr(buf.Bytes(), "Security", "mustUnderstand")
+			if !ok {
+				t.Fatalf("mustUnderstand attribute not found on Security element: %s", buf.String())
+			}
+			if attr.Name.Space != tc.wantNS {
+				t.Errorf("mustUnderstand namespace = %q, want %q", attr.Name.Space, tc.wantNS)
+			}
+			if attr.Value != "1" {
+				t.Errorf("mustUnderstand value = %q, want %q", attr.Value, "1")
+			}
+
+			typeAttr, ok := findAttr(buf.Bytes(), "Password", "Type")
+			if !ok {
+				t.Fatalf("Type attribute not found on Password element: %s", buf.String())
+			}
+			if typeAttr.Value != passwordDigestType {
+				t.Errorf("Password Type = %q, want %q", typeAttr.Value, passwordDigestType)
+			}
+		})
+	}
+}
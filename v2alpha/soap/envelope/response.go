@@ -0,0 +1,35 @@
+package envelope
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ReadResponse reads a SOAP envelope from r, decodes the body's action
+// element into out, and verifies that the element's XMLName matches
+// expectedName (conventionally the action name suffixed with "Response",
+// in the service's namespace). It returns *Fault if the envelope carries
+// a fault instead of a response.
+func ReadResponse(r io.Reader, expectedName xml.Name, out any) error {
+	action := &Action{Args: out}
+	if err := Read(r, action); err != nil {
+		return err
+	}
+	if action.XMLName != expectedName {
+		return fmt.Errorf("envelope: response action %s does not match expected %s", action.XMLName, expectedName)
+	}
+	return nil
+}
+
+// ReadRequest reads a SOAP envelope from r and decodes the body's action
+// arguments into out, returning the action's observed XMLName. Unlike
+// ReadResponse, it does not know the action name up front; this lets a
+// server-side SOAP handler dispatch on the returned name.
+func ReadRequest(r io.Reader, out any) (xml.Name, error) {
+	action := &Action{Args: out}
+	if err := Read(r, action); err != nil {
+		return xml.Name{}, err
+	}
+	return action.XMLName, nil
+}
@@ -0,0 +1,57 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+func TestReadResponseMatches(t *testing.T) {
+	var buf bytes.Buffer
+	name := xml.Name{Space: "urn:test", Local: "EchoResponse"}
+	if err := Write(&buf, &Action{XMLName: name, Args: echoArgs{Value: "hi"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var out echoArgs
+	if err := ReadResponse(&buf, name, &out); err != nil {
+		t.Fatalf("ReadResponse: %v", err)
+	}
+	if out.Value != "hi" {
+		t.Errorf("Value = %q, want %q", out.Value, "hi")
+	}
+}
+
+func TestReadResponseNameMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	actual := xml.Name{Space: "urn:test", Local: "EchoResponse"}
+	if err := Write(&buf, &Action{XMLName: actual, Args: echoArgs{Value: "hi"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	expected := xml.Name{Space: "urn:test", Local: "SomeOtherResponse"}
+	var out echoArgs
+	if err := ReadResponse(&buf, expected, &out); err == nil {
+		t.Fatal("ReadResponse err = nil, want error for name mismatch")
+	}
+}
+
+func TestReadRequestReportsName(t *testing.T) {
+	var buf bytes.Buffer
+	name := xml.Name{Space: "urn:test", Local: "Echo"}
+	if err := Write(&buf, &Action{XMLName: name, Args: echoArgs{Value: "hi"}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	var out echoArgs
+	got, err := ReadRequest(&buf, &out)
+	if err != nil {
+		t.Fatalf("ReadRequest: %v", err)
+	}
+	if got != name {
+		t.Errorf("name = %v, want %v", got, name)
+	}
+	if out.Value != "hi" {
+		t.Errorf("Value = %q, want %q", out.Value, "hi")
+	}
+}
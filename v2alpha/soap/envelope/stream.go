@@ -0,0 +1,185 @@
+package envelope
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ActionEncoder streams a SOAP action's arguments one at a time, instead
+// of requiring them to be materialized into a single Args struct up
+// front as Write does. This avoids buffering large responses (e.g. a
+// ContentDirectory Browse/Search result) fully in memory.
+//
+// This package has no HTTP/SOAP client of its own to wire ActionEncoder
+// and ActionDecoder into yet; callers write to and read from a
+// transport's request/response bodies directly until one exists.
+type ActionEncoder struct {
+	w      io.Writer
+	enc    *xml.Encoder
+	name   xml.Name
+	closed bool
+}
+
+// NewEncoder writes the envelope/body/action-open preamble for an action
+// named name to w, and returns an ActionEncoder ready for
+// argument-by-argument encoding via EncodeElement. The caller must call
+// Close to emit the closing tags.
+func NewEncoder(w io.Writer, name xml.Name) (*ActionEncoder, error) {
+	if _, err := w.Write(envOpen); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(bodyOpen); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(env1); err != nil {
+		return nil, err
+	}
+	if err := xml.EscapeText(w, []byte(name.Local)); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(env2); err != nil {
+		return nil, err
+	}
+	if err := xml.EscapeText(w, []byte(name.Space)); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(env3); err != nil {
+		return nil, err
+	}
+	return &ActionEncoder{w: w, enc: xml.NewEncoder(w), name: name}, nil
+}
+
+// EncodeElement encodes v as a single child element of the action, using
+// start as its tag. Call Flush or Close to guarantee the bytes reach w.
+func (e *ActionEncoder) EncodeElement(v any, start xml.StartElement) error {
+	return e.enc.EncodeElement(v, start)
+}
+
+// Flush flushes any argument XML buffered by EncodeElement to w.
+func (e *ActionEncoder) Flush() error {
+	return e.enc.Flush()
+}
+
+// Close flushes any buffered argument XML and writes the action/body/
+// envelope closing tags. It does not close the underlying writer. Close
+// is a no-op after the first call.
+func (e *ActionEncoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if err := e.enc.Flush(); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(env4); err != nil {
+		return err
+	}
+	if err := xml.EscapeText(e.w, []byte(e.name.Local)); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(env5); err != nil {
+		return err
+	}
+	_, err := e.w.Write(envClose)
+	return err
+}
+
+// ActionDecoder streams a SOAP response's out-arguments one at a time,
+// instead of requiring the whole body to be decoded into a single struct
+// as Read does. NewDecoder parses through the envelope, any header, and
+// <s:Body> up to the action element; a *Fault found in the body instead
+// of an action is returned as the error, even though none of the action's
+// arguments have been read yet.
+type ActionDecoder struct {
+	dec  *xml.Decoder
+	name xml.Name
+}
+
+// NewDecoder parses r up to the action (or action-response) element and
+// returns an ActionDecoder ready to decode individual arguments.
+func NewDecoder(r io.Reader) (*ActionDecoder, error) {
+	dec := xml.NewDecoder(r)
+
+	envStart, err := nextStartElement(dec) // <s:Envelope>
+	if err != nil {
+		return nil, err
+	}
+	if envStart.Name.Local != "Envelope" {
+		return nil, fmt.Errorf("envelope: root element %s is not a SOAP Envelope", envStart.Name)
+	}
+	version := SOAP11
+	envNS := soap11NS
+	if envStart.Name.Space == soap12NS {
+		version = SOAP12
+		envNS = soap12NS
+	}
+
+	for {
+		start, err := nextStartElement(dec)
+		if err != nil {
+			return nil, err
+		}
+		if start.Name.Space == envNS {
+			switch start.Name.Local {
+			case "Header":
+				if err := readHeaders(dec, nil); err != nil {
+					return nil, err
+				}
+				continue
+			case "Body":
+				return newActionDecoder(dec, version)
+			}
+		}
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newActionDecoder(dec *xml.Decoder, version Version) (*ActionDecoder, error) {
+	start, err := nextStartElement(dec)
+	if err != nil {
+		return nil, err
+	}
+	if start.Name.Local == "Fault" {
+		return nil, decodeFault(dec, start, version)
+	}
+	return &ActionDecoder{dec: dec, name: start.Name}, nil
+}
+
+// Name reports the XML name of the action element being decoded.
+func (d *ActionDecoder) Name() xml.Name {
+	return d.name
+}
+
+// DecodeArg decodes the next argument element named name into v. It
+// returns io.EOF once the action element has no more children.
+func (d *ActionDecoder) DecodeArg(name string, v any) error {
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != name {
+				if err := d.dec.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			return d.dec.DecodeElement(v, &t)
+		case xml.EndElement:
+			return io.EOF
+		}
+	}
+}
+
+// Token returns the next raw XML token within the action element, for
+// callers that want to walk the arguments by hand instead of using
+// DecodeArg.
+func (d *ActionDecoder) Token() (xml.Token, error) {
+	return d.dec.Token()
+}
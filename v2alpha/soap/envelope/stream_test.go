@@ -0,0 +1,76 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestActionEncoderDecoderRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	name := xml.Name{Space: "urn:test", Local: "BrowseResponse"}
+
+	enc, err := NewEncoder(&buf, name)
+	if err != nil {
+		t.Fatalf("NewEncoder: %v", err)
+	}
+	if err := enc.EncodeElement("hello", xml.StartElement{Name: xml.Name{Local: "Result"}}); err != nil {
+		t.Fatalf("EncodeElement: %v", err)
+	}
+	if err := enc.EncodeElement(42, xml.StartElement{Name: xml.Name{Local: "NumberReturned"}}); err != nil {
+		t.Fatalf("EncodeElement: %v", err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	dec, err := NewDecoder(&buf)
+	if err != nil {
+		t.Fatalf("NewDecoder: %v", err)
+	}
+	if dec.Name() != name {
+		t.Errorf("Name() = %v, want %v", dec.Name(), name)
+	}
+
+	var result string
+	if err := dec.DecodeArg("Result", &result); err != nil {
+		t.Fatalf("DecodeArg(Result): %v", err)
+	}
+	if result != "hello" {
+		t.Errorf("Result = %q, want %q", result, "hello")
+	}
+
+	var n int
+	if err := dec.DecodeArg("NumberReturned", &n); err != nil {
+		t.Fatalf("DecodeArg(NumberReturned): %v", err)
+	}
+	if n != 42 {
+		t.Errorf("NumberReturned = %d, want %d", n, 42)
+	}
+}
+
+func TestActionDecoderFault(t *testing.T) {
+	raw := xml.Header + `<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">` +
+		`<s:Body><s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring></s:Fault></s:Body>` +
+		`</s:Envelope>`
+
+	_, err := NewDecoder(strings.NewReader(raw))
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("NewDecoder err = %v, want *Fault", err)
+	}
+	if fault.String != "UPnPError" {
+		t.Errorf("fault.String = %q, want %q", fault.String, "UPnPError")
+	}
+}
+
+func TestNewDecoderRejectsNonEnvelopeRoot(t *testing.T) {
+	raw := xml.Header + `<NotAnEnvelope/>`
+	_, err := NewDecoder(strings.NewReader(raw))
+	if err == nil {
+		t.Fatal("NewDecoder err = nil, want error")
+	}
+}
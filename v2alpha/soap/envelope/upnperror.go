@@ -0,0 +1,134 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// upnpErrorNS is the namespace of the UPnPError element that UPnP services
+// use to report per-action errors inside a SOAP Fault's <detail>.
+const upnpErrorNS = "urn:schemas-upnp-org:control-1-0"
+
+// UPnPError is the urn:schemas-upnp-org:control-1-0 UPnPError detail that
+// UPnP services use to report per-action errors. Fault.UPnPError decodes
+// one out of a Fault's detail.
+type UPnPError struct {
+	XMLName     xml.Name `xml:"urn:schemas-upnp-org:control-1-0 UPnPError"`
+	Code        uint     `xml:"urn:schemas-upnp-org:control-1-0 errorCode"`
+	Description string   `xml:"urn:schemas-upnp-org:control-1-0 errorDescription"`
+}
+
+func (e *UPnPError) Error() string {
+	return fmt.Sprintf("UPnP error %d: %s", e.Code, e.Description)
+}
+
+// Well-known UPnP error codes, as defined by the UPnP Device Architecture
+// and the individual service specifications that layer on top of it.
+const (
+	ErrorInvalidAction                = 401 // No action by that name.
+	ErrorInvalidArgs                  = 402 // Invalid number or value of arguments.
+	ErrorActionFailed                 = 501 // Device failed to complete the action.
+	ErrorArgumentValueInvalid         = 600 // Argument value is invalid.
+	ErrorArgumentValueOutOfRange      = 601
+	ErrorOptionalActionNotImplemented = 602
+	ErrorOutOfMemory                  = 603
+	ErrorHumanInterventionRequired    = 604
+	ErrorStringArgumentTooLong        = 605
+
+	// AVTransport-specific errors (urn:schemas-upnp-org:service:AVTransport).
+	ErrorAVTransportTransitionNotAvailable         = 701
+	ErrorAVTransportNoContents                     = 702
+	ErrorAVTransportReadError                      = 703
+	ErrorAVTransportFormatNotSupportedForPlayback  = 704
+	ErrorAVTransportTransportIsLocked              = 705
+	ErrorAVTransportWriteError                     = 706
+	ErrorAVTransportMediaProtected                 = 707
+	ErrorAVTransportFormatNotSupportedForRecording = 708
+	ErrorAVTransportMediaIsFull                    = 709
+	ErrorAVTransportSeekModeNotSupported           = 710
+	ErrorAVTransportIllegalSeekTarget              = 711
+	ErrorAVTransportPlayModeNotSupported           = 712
+	ErrorAVTransportRecordQualityNotSupported      = 713
+	ErrorAVTransportIllegalMIMEType                = 714
+	ErrorAVTransportContentBusy                    = 715
+	ErrorAVTransportResourceNotFound               = 716
+	ErrorAVTransportPlaySpeedNotSupported          = 717
+	ErrorAVTransportInvalidInstanceID              = 718
+)
+
+// detailRegistry maps a Fault detail element's XMLName to a constructor
+// for the Go type services use to decode it. Fault.Detail itself keeps only
+// the raw bytes; Fault.UPnPError and Fault.Unwrap decode them on demand.
+var detailRegistry = map[xml.Name]func() any{}
+
+func init() {
+	RegisterDetail(xml.Name{Space: upnpErrorNS, Local: "UPnPError"}, func() any { return &UPnPError{} })
+}
+
+// RegisterDetail registers a constructor for a SOAP Fault detail element
+// named name, so that Fault.Unwrap decodes a fault's detail into the value
+// it returns whenever the detail carries that element. Services with their own detail
+// schema beyond the shared UPnPError can call this from an init func.
+func RegisterDetail(name xml.Name, newDetail func() any) {
+	detailRegistry[name] = newDetail
+}
+
+// detailElementName returns the XML name of detail's wrapped element,
+// e.g. UPnPError for a UPnP service's fault detail.
+func (d FaultDetail) detailElementName() (xml.Name, bool) {
+	dec := xml.NewDecoder(bytes.NewReader(d.Raw))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.Name{}, false
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name, true
+		}
+	}
+}
+
+// decode unmarshals d's wrapped element into the type registered for its
+// XMLName via RegisterDetail, if any.
+func (d FaultDetail) decode() (any, bool) {
+	name, ok := d.detailElementName()
+	if !ok {
+		return nil, false
+	}
+	newDetail, ok := detailRegistry[name]
+	if !ok {
+		return nil, false
+	}
+	v := newDetail()
+	if err := xml.Unmarshal(d.Raw, v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// UPnPError returns the fault's detail decoded as a UPnPError, and true,
+// if the detail is registered as one. errors.As(err, &upe) also works
+// against an error returned from Read, via Unwrap.
+func (fe *Fault) UPnPError() (*UPnPError, bool) {
+	v, ok := fe.Detail.decode()
+	if !ok {
+		return nil, false
+	}
+	upe, ok := v.(*UPnPError)
+	return upe, ok
+}
+
+// Unwrap returns the fault's decoded detail as an error, if its type is
+// registered (via RegisterDetail) and implements error, so that
+// errors.As can extract it from an error chain rooted at this Fault.
+func (fe *Fault) Unwrap() error {
+	v, ok := fe.Detail.decode()
+	if !ok {
+		return nil
+	}
+	if err, ok := v.(error); ok {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package envelope
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFaultUPnPErrorErrorsAs(t *testing.T) {
+	raw := `<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/">
+<s:Body><s:Fault>
+<faultcode>s:Client</faultcode>
+<faultstring>UPnPError</faultstring>
+<detail><UPnPError xmlns="urn:schemas-upnp-org:control-1-0">
+<errorCode>401</errorCode>
+<errorDescription>Invalid Action</errorDescription>
+</UPnPError></detail>
+</s:Fault></s:Body></s:Envelope>`
+
+	action := &Action{Args: &noArgs{}}
+	err := Read(strings.NewReader(raw), action)
+
+	var fault *Fault
+	if !errors.As(err, &fault) {
+		t.Fatalf("Read err = %v, want *Fault", err)
+	}
+
+	upe, ok := fault.UPnPError()
+	if !ok {
+		t.Fatalf("fault.UPnPError() ok = false, want true")
+	}
+	if upe.Code != ErrorInvalidAction {
+		t.Errorf("Code = %d, want %d", upe.Code, ErrorInvalidAction)
+	}
+	if upe.Description != "Invalid Action" {
+		t.Errorf("Description = %q, want %q", upe.Description, "Invalid Action")
+	}
+
+	var viaUnwrap *UPnPError
+	if !errors.As(err, &viaUnwrap) {
+		t.Fatalf("errors.As(err, &UPnPError) = false, want true via Fault.Unwrap")
+	}
+	if viaUnwrap.Code != ErrorInvalidAction {
+		t.Errorf("Code via errors.As = %d, want %d", viaUnwrap.Code, ErrorInvalidAction)
+	}
+}
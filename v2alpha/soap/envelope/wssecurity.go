@@ -0,0 +1,103 @@
+package envelope
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"time"
+)
+
+// WS-Security namespaces used by UPnP DeviceProtection to authenticate
+// control actions.
+const (
+	wsseNS = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	wsuNS  = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+
+	// passwordDigestType is the UsernameToken Profile Type value that
+	// marks a <wsse:Password> as carrying a digest rather than the
+	// plaintext password. Without it, a compliant receiver defaults to
+	// PasswordText and compares the digest string as if it were the
+	// password itself, so authentication always fails.
+	passwordDigestType = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+)
+
+// wssPassword is a WS-Security <wsse:Password>, tagged with the Type
+// attribute identifying its content as a PasswordDigest.
+type wssPassword struct {
+	Type  string `xml:"Type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// UsernameToken is a WS-Security UsernameToken, carrying a username and a
+// nonce-and-timestamp password digest. Construct one with
+// NewUsernameToken rather than filling it in by hand.
+type UsernameToken struct {
+	XMLName  xml.Name    `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd UsernameToken"`
+	Username string      `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Username"`
+	Password wssPassword `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Password"`
+	Nonce    string      `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Nonce"`
+	Created  string      `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd Created"`
+}
+
+// PasswordDigest returns the token's base64-encoded password digest.
+func (t *UsernameToken) PasswordDigest() string {
+	return t.Password.Value
+}
+
+// NewUsernameToken builds a UsernameToken authenticating username with
+// password, as required by UPnP DeviceProtection. The digest is computed
+// as base64(SHA1(nonce + created + password)) over a freshly generated
+// nonce and the current time, per the WS-Security UsernameToken profile.
+func NewUsernameToken(username, password string) (*UsernameToken, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	created := time.Now().UTC().Format(time.RFC3339)
+
+	h := sha1.New()
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(password))
+
+	return &UsernameToken{
+		Username: username,
+		Password: wssPassword{
+			Type:  passwordDigestType,
+			Value: base64.StdEncoding.EncodeToString(h.Sum(nil)),
+		},
+		Nonce:   base64.StdEncoding.EncodeToString(nonce),
+		Created: created,
+	}, nil
+}
+
+// HeaderName identifies a UsernameToken header for Read to match against.
+func (t *UsernameToken) HeaderName() xml.Name {
+	return xml.Name{Space: wsseNS, Local: "UsernameToken"}
+}
+
+// Security is the WS-Security <Security> header that UPnP DeviceProtection
+// wraps a UsernameToken in. It implements MustUnderstandHeader, so Write
+// marks it mustUnderstand="1" in whichever envelope namespace matches the
+// Version being written; a device that does not implement DeviceProtection
+// then rejects the request instead of silently ignoring the credentials.
+type Security struct {
+	XMLName       xml.Name       `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd Security"`
+	UsernameToken *UsernameToken `xml:"http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd UsernameToken"`
+}
+
+// NewSecurity wraps token in a Security header.
+func NewSecurity(token *UsernameToken) *Security {
+	return &Security{UsernameToken: token}
+}
+
+// HeaderName identifies a Security header for Read to match against.
+func (s *Security) HeaderName() xml.Name {
+	return xml.Name{Space: wsseNS, Local: "Security"}
+}
+
+// MustUnderstand reports true: see the Security doc comment.
+func (s *Security) MustUnderstand() bool {
+	return true
+}